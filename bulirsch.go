@@ -0,0 +1,98 @@
+package ode
+
+// bsSubsteps is the Deuflhard sequence of modified-midpoint substep
+// counts used by BulirschStoer, increasing by 2 each column.
+var bsSubsteps = []int{2, 4, 6, 8, 10, 12, 14, 16}
+
+// modifiedMidpoint advances xx over H using n substeps of the
+// (leap-frog style) modified midpoint rule, which is the building block
+// BulirschStoer extrapolates to the limit n -> infinity.
+func modifiedMidpoint(xx []Num, t, H Num, n int, dxdt []Ode) []Num {
+	nEq := len(xx)
+	h := H / Num(n)
+
+	zPrev := make([]Num, nEq)
+	copy(zPrev, xx)
+
+	f0 := evalDerivs(dxdt, zPrev, t)
+
+	zCur := make([]Num, nEq)
+	for i := range xx {
+		zCur[i] = zPrev[i] + h*f0[i]
+	}
+
+	for m := 1; m < n; m++ {
+		fm := evalDerivs(dxdt, zCur, t+Num(m)*h)
+
+		zNext := make([]Num, nEq)
+		for i := range xx {
+			zNext[i] = zPrev[i] + 2*h*fm[i]
+		}
+
+		zPrev, zCur = zCur, zNext
+	}
+
+	fn := evalDerivs(dxdt, zCur, t+H)
+
+	x := make([]Num, nEq)
+	for i := range xx {
+		x[i] = 0.5 * (zCur[i] + zPrev[i] + h*fn[i])
+	}
+
+	return x
+}
+
+// BulirschStoer is suited to smooth, non-stiff problems where very high
+// accuracy is wanted cheaply. It advances a (potentially large) step h by
+// running modified-midpoint substep sequences of increasing refinement
+// and extrapolating the sequence of endpoint estimates to the limit of
+// infinitely many substeps with Neville's polynomial extrapolation in
+// h^2. The change between the last two extrapolated
+// columns is used as the error estimate, so it plugs straight into
+// AdaptiveEmbedded like DoPri45 and Ros23s.
+func BulirschStoer(xx []Num, t, h Num, dxdt []Ode, dx0 []Num) (kk, errEst, dxEnd []Num) {
+	n := len(xx)
+
+	xs := make([]Num, len(bsSubsteps))
+	table := make([][][]Num, len(bsSubsteps)) // table[k][j] is the j-th extrapolation of column k
+
+	var row [][]Num
+
+	for k, nSub := range bsSubsteps {
+		hSub := h / Num(nSub)
+		xs[k] = hSub * hSub
+
+		row = make([][]Num, k+1)
+		row[0] = modifiedMidpoint(xx, t, h, nSub, dxdt)
+
+		for j := 1; j <= k; j++ {
+			prevRow := table[k-1]
+
+			cur := make([]Num, n)
+			factor := xs[k-j]/xs[k] - 1
+
+			for i := 0; i < n; i++ {
+				cur[i] = row[j-1][i] + (row[j-1][i]-prevRow[j-1][i])/factor
+			}
+
+			row[j] = cur
+		}
+
+		table[k] = row
+	}
+
+	last := len(bsSubsteps) - 1
+
+	kk = make([]Num, n)
+	errEst = make([]Num, n)
+
+	for i := range xx {
+		kk[i] = row[last][i] - xx[i]
+		errEst[i] = row[last][i] - row[last-1][i]
+	}
+
+	// the extrapolated endpoint isn't a single stage's derivative, so
+	// there's no FSAL value to hand back; dx0 isn't used either, since
+	// modifiedMidpoint always evaluates its own first substep.
+	return kk, errEst, nil
+}
@@ -0,0 +1,54 @@
+package ode
+
+import "testing"
+
+// keplerOdes returns the eccentricity-0.5 two-body test problem used by
+// Example_BulirschStoer, with every derivative evaluation counted.
+func keplerOdes(evals *int) ([]Ode, []Num) {
+	const e = 0.5
+
+	count := func() { *evals++ }
+
+	dxdt := func(xx []Num, t Num) Num { count(); return xx[2] }
+	dydt := func(xx []Num, t Num) Num { count(); return xx[3] }
+	dvxdt := func(xx []Num, t Num) Num {
+		count()
+		x, y := xx[0], xx[1]
+		return -x / pow(x*x+y*y, 1.5)
+	}
+	dvydt := func(xx []Num, t Num) Num {
+		count()
+		x, y := xx[0], xx[1]
+		return -y / pow(x*x+y*y, 1.5)
+	}
+
+	xx := []Num{1 - e, 0, 0, Num(pow((1+e)/(1-e), 0.5))}
+
+	return []Ode{dxdt, dydt, dvxdt, dvydt}, xx
+}
+
+// BenchmarkKeplerBulirschStoerVsRk4 compares the number of derivative
+// evaluations BulirschStoer and the step-doubled Rk4 need to integrate
+// one period of the Kepler orbit at a tight tolerance; BulirschStoer's
+// high order lets it take far fewer, larger steps for the same accuracy.
+func BenchmarkKeplerBulirschStoerVsRk4(b *testing.B) {
+	const period = 2 * 3.141592653589793
+
+	b.Run("BulirschStoer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var evals int
+			odes, xx := keplerOdes(&evals)
+			AdaptiveEmbedded(BulirschStoer, odes, xx, 0, period, 0.1, 1e-10, 1e-10, 6)
+			b.ReportMetric(float64(evals), "evals")
+		}
+	})
+
+	b.Run("Rk4", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var evals int
+			odes, xx := keplerOdes(&evals)
+			AdaptiveStep(Rk4, odes, xx, 0, period, 1e-8, 0.01)
+			b.ReportMetric(float64(evals), "evals")
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package ode
+
+import "fmt"
+
+func Example_BulirschStoer() {
+	// two-body problem with eccentricity e=0.5, period 2*pi (Hairer's
+	// standard test orbit)
+	const e = 0.5
+
+	dxdt := func(xx []Num, t Num) Num { return xx[2] }
+	dydt := func(xx []Num, t Num) Num { return xx[3] }
+	dvxdt := func(xx []Num, t Num) Num {
+		x, y := xx[0], xx[1]
+		r3 := pow(x*x+y*y, 1.5)
+		return -x / r3
+	}
+	dvydt := func(xx []Num, t Num) Num {
+		x, y := xx[0], xx[1]
+		r3 := pow(x*x+y*y, 1.5)
+		return -y / r3
+	}
+
+	odes := []Ode{dxdt, dydt, dvxdt, dvydt}
+
+	xx := []Num{1 - e, 0, 0, Num(pow((1+e)/(1-e), 0.5))}
+
+	result := AdaptiveEmbedded(BulirschStoer, odes, xx, 0, 2*3.141592653589793, 0.1, 1e-10, 1e-10, 6)
+
+	final := result.Interpolate(2 * 3.141592653589793)
+
+	fmt.Printf("%.3f %.3f\n", final[0], final[1])
+
+	// output:
+	// 0.500 0.000
+}
@@ -0,0 +1,70 @@
+package ode
+
+import "sort"
+
+// Results is the time-ordered sequence of accepted steps returned by the
+// drivers. Because each Result carries the derivative at its own t (see
+// evalDerivs), Results supports continuous ("dense") output between grid
+// points via Interpolate and Sample, independent of the integrator's own
+// step size.
+type Results []Result
+
+// Interpolate evaluates the trajectory at an arbitrary time t by locating
+// the step enclosing t with a binary search and returning the cubic
+// Hermite interpolation between its endpoints.
+func (r Results) Interpolate(t Num) []Num {
+	if len(r) < 2 {
+		return nil
+	}
+
+	i := sort.Search(len(r), func(i int) bool { return r[i].t >= t })
+
+	switch {
+	case i <= 0:
+		i = 1
+	case i >= len(r):
+		i = len(r) - 1
+	}
+
+	return hermite(r[i-1], r[i], t)
+}
+
+// Sample evaluates the trajectory at each of times, which must be sorted
+// ascending, walking the Results grid in a single pass.
+func (r Results) Sample(times []Num) [][]Num {
+	out := make([][]Num, len(times))
+
+	if len(r) < 2 {
+		return out
+	}
+
+	i := 1
+	for j, t := range times {
+		for i < len(r)-1 && r[i].t < t {
+			i++
+		}
+
+		out[j] = hermite(r[i-1], r[i], t)
+	}
+
+	return out
+}
+
+// hermite interpolates x(t) between a and b using the cubic Hermite basis
+// h00, h10, h01, h11, with s the fraction of the step a-b at which t lies.
+func hermite(a, b Result, t Num) []Num {
+	h := b.t - a.t
+	s := (t - a.t) / h
+
+	h00 := (1 + 2*s) * (1 - s) * (1 - s)
+	h10 := s * (1 - s) * (1 - s)
+	h01 := s * s * (3 - 2*s)
+	h11 := s * s * (s - 1)
+
+	xx := make([]Num, len(a.xx))
+	for i := range xx {
+		xx[i] = h00*a.xx[i] + h10*h*a.dx[i] + h01*b.xx[i] + h11*h*b.dx[i]
+	}
+
+	return xx
+}
@@ -0,0 +1,18 @@
+package ode
+
+import "fmt"
+
+func Example_Interpolate() {
+	decay := func(xx []Num, t Num) Num { return -xx[0] }
+
+	odes := []Ode{decay}
+
+	result := FixedStep(Euler, odes, []Num{1}, 0, 2, 0.5)
+
+	xx := result.Interpolate(1.25)
+
+	fmt.Printf("%.6f\n", xx[0])
+
+	// output:
+	// 0.179688
+}
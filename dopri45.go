@@ -0,0 +1,238 @@
+package ode
+
+import "math"
+
+// EmbeddedIntegrator is an integration method that, besides the step
+// increment, also returns a per-component error estimate obtained from
+// an embedded lower order companion method. dx0, if non-nil, is the
+// derivative at (xx, t) already known to the caller (e.g. carried
+// forward from the previous step's FSAL stage), letting the method skip
+// recomputing it; dxEnd is the derivative at the landing state xx+kk if
+// the method gets it for free (first-same-as-last methods such as
+// DoPri45), or nil otherwise.
+type EmbeddedIntegrator func(xx []Num, t, h Num, dxdt []Ode, dx0 []Num) (kk, errEst, dxEnd []Num)
+
+// DoPri45 is the Dormand-Prince 5(4) pair, the method behind MATLAB's and
+// Octave's ode45. It advances xx with a 5th order solution while the
+// difference with an embedded 4th order solution gives a cheap error
+// estimate for adaptive step control (see AdaptiveEmbedded). Being FSAL
+// (first-same-as-last), its last stage is the derivative at the landing
+// state, which it hands back as dxEnd so the caller can feed it in as
+// the next step's dx0 instead of re-evaluating dxdt.
+func DoPri45(xx []Num, t, h Num, dxdt []Ode, dx0 []Num) (kk, errEst, dxEnd []Num) {
+	n := len(xx)
+
+	dd1 := make([]Num, n)
+	dd2 := make([]Num, n)
+	dd3 := make([]Num, n)
+	dd4 := make([]Num, n)
+	dd5 := make([]Num, n)
+	dd6 := make([]Num, n)
+	dd7 := make([]Num, n)
+
+	xxNext := make([]Num, n)
+
+	if dx0 != nil {
+		copy(dd1, dx0)
+	} else {
+		for i, f := range dxdt {
+			dd1[i] = f(xx, t)
+		}
+	}
+
+	for i := range xx {
+		xxNext[i] = xx[i] + h*(1.0/5.0)*dd1[i]
+	}
+	for i, f := range dxdt {
+		dd2[i] = f(xxNext, t+h*(1.0/5.0))
+	}
+
+	for i := range xx {
+		xxNext[i] = xx[i] + h*((3.0/40.0)*dd1[i]+(9.0/40.0)*dd2[i])
+	}
+	for i, f := range dxdt {
+		dd3[i] = f(xxNext, t+h*(3.0/10.0))
+	}
+
+	for i := range xx {
+		xxNext[i] = xx[i] + h*((44.0/45.0)*dd1[i]-(56.0/15.0)*dd2[i]+(32.0/9.0)*dd3[i])
+	}
+	for i, f := range dxdt {
+		dd4[i] = f(xxNext, t+h*(4.0/5.0))
+	}
+
+	for i := range xx {
+		xxNext[i] = xx[i] + h*((19372.0/6561.0)*dd1[i]-(25360.0/2187.0)*dd2[i]+(64448.0/6561.0)*dd3[i]-(212.0/729.0)*dd4[i])
+	}
+	for i, f := range dxdt {
+		dd5[i] = f(xxNext, t+h*(8.0/9.0))
+	}
+
+	for i := range xx {
+		xxNext[i] = xx[i] + h*((9017.0/3168.0)*dd1[i]-(355.0/33.0)*dd2[i]+(46732.0/5247.0)*dd3[i]+(49.0/176.0)*dd4[i]-(5103.0/18656.0)*dd5[i])
+	}
+	for i, f := range dxdt {
+		dd6[i] = f(xxNext, t+h)
+	}
+
+	// 5th order solution, also the 7th (FSAL) stage derivative
+	for i := range xx {
+		xxNext[i] = xx[i] + h*((35.0/384.0)*dd1[i]+(500.0/1113.0)*dd3[i]+(125.0/192.0)*dd4[i]-(2187.0/6784.0)*dd5[i]+(11.0/84.0)*dd6[i])
+	}
+	for i, f := range dxdt {
+		dd7[i] = f(xxNext, t+h)
+	}
+
+	kk = make([]Num, n)
+	errEst = make([]Num, n)
+
+	for i := range xx {
+		kk[i] = h * ((35.0/384.0)*dd1[i] + (500.0/1113.0)*dd3[i] + (125.0/192.0)*dd4[i] - (2187.0/6784.0)*dd5[i] + (11.0/84.0)*dd6[i])
+
+		x5 := xx[i] + kk[i]
+		x4 := xx[i] + h*((5179.0/57600.0)*dd1[i]+(7571.0/16695.0)*dd3[i]+(393.0/640.0)*dd4[i]-(92097.0/339200.0)*dd5[i]+(187.0/2100.0)*dd6[i]+(1.0/40.0)*dd7[i])
+
+		errEst[i] = x5 - x4
+	}
+
+	return kk, errEst, dd7
+}
+
+// AdaptiveEmbedded iterates over a set of ode's with an embedded
+// integrator, using PI step-size control based on the per-step error
+// estimate instead of the step-doubling heuristic used by AdaptiveStep.
+// atol and rtol are the absolute and relative tolerances used to scale
+// the error norm; order is the order of method's *embedded* error
+// estimator, not the higher-order solution it advances with (4 for
+// DoPri45's 4th order companion, 1 for Ros23s's Rosenbrock-Euler stage,
+// ...), and sets the PI controller's convergence-rate exponent.
+func AdaptiveEmbedded(method EmbeddedIntegrator, dxdt []Ode, xx []Num, t0, tmax, h, atol, rtol Num, order int) Results {
+	const (
+		safety = 0.9
+		minFac = 0.1
+		maxFac = 5.0
+	)
+
+	var T Num = t0
+	var c Num // Kahan compensation, see kahanAdd
+
+	prevErr := Num(1)
+
+	r := make(Results, 0, 200)
+
+	var last Result
+	haveLast := false
+
+	// dx0 carries an FSAL method's last-stage derivative forward as the
+	// next step's first-stage derivative, saving a dxdt evaluation; it's
+	// nil for methods (Ros23s, BulirschStoer, ...) that don't return one.
+	var dx0 []Num
+
+	for T <= tmax {
+		var kk, dxEnd []Num
+		var errNorm Num
+
+		// max 5 decrements, same bound AdaptiveStep uses, plus a guard
+		// against a NaN/Inf errNorm (from a diverging or ill-defined
+		// dxdt) which would otherwise never satisfy errNorm <= 1 and spin
+		// forever.
+		for attempt := 0; attempt < 5; attempt++ {
+			var errEst []Num
+			kk, errEst, dxEnd = method(xx, T, h, dxdt, dx0)
+
+			errNorm = embeddedErrorNorm(xx, kk, errEst, atol, rtol)
+
+			if !finite(errNorm) || errNorm <= 1 {
+				break
+			}
+
+			h *= clamp(safety*pow(1/errNorm, 1.0/Num(order+1)), minFac, 1)
+		}
+
+		x := make([]Num, len(xx))
+		copy(x, xx)
+
+		r = append(r, Result{T, x, derivOr(dx0, dxdt, xx, T)})
+
+		for i, k := range kk {
+			xx[i] += k
+		}
+
+		T, c = kahanAdd(T, h, c)
+
+		xEnd := make([]Num, len(xx))
+		copy(xEnd, xx)
+
+		dx0 = dxEnd
+		last, haveLast = Result{T, xEnd, derivOr(dxEnd, dxdt, xx, T)}, true
+
+		factor := clamp(safety*pow(1/errNorm, 1.0/Num(order+1))*pow(prevErr/errNorm, 0.08), minFac, maxFac)
+		h *= factor
+
+		if errNorm > 0 {
+			prevErr = errNorm
+		}
+	}
+
+	// the loop above only appends each step's pre-step state; append the
+	// final accepted step's landing state too, so the trajectory actually
+	// reaches tmax instead of falling short by up to one full step.
+	if haveLast {
+		r = append(r, last)
+	}
+
+	return r
+}
+
+// embeddedErrorNorm computes the weighted RMS norm of errEst scaled by
+// the absolute and relative tolerances, as used by AdaptiveEmbedded.
+func embeddedErrorNorm(xxOld, kk, errEst []Num, atol, rtol Num) Num {
+	var sum Num
+
+	for i, e := range errEst {
+		xNew := xxOld[i] + kk[i]
+
+		xAbs := xxOld[i]
+		if xAbs < 0 {
+			xAbs = -xAbs
+		}
+
+		xNewAbs := xNew
+		if xNewAbs < 0 {
+			xNewAbs = -xNewAbs
+		}
+
+		maxAbs := xAbs
+		if xNewAbs > maxAbs {
+			maxAbs = xNewAbs
+		}
+
+		sc := atol + rtol*maxAbs
+
+		v := e / sc
+		sum += v * v
+	}
+
+	return Num(math.Sqrt(float64(sum / Num(len(errEst)))))
+}
+
+// clamp restricts v to [lo, hi].
+func clamp(v, lo, hi Num) Num {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// pow is a small wrapper so the Num type doesn't leak into every call site.
+func pow(x, y Num) Num {
+	return Num(math.Pow(float64(x), float64(y)))
+}
+
+// finite reports whether x is neither NaN nor +/-Inf.
+func finite(x Num) bool {
+	return !math.IsNaN(float64(x)) && !math.IsInf(float64(x), 0)
+}
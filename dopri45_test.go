@@ -0,0 +1,25 @@
+package ode
+
+import "fmt"
+
+func Example_DoPri45() {
+	var k, m, b Num
+
+	k = 1
+	m = 1
+	b = 0.4
+
+	dxdt := func(xx []Num, t Num) Num { v := xx[1]; return v }
+	dvdt := func(xx []Num, t Num) Num { x, v := xx[0], xx[1]; return -k*x/m - b*v/m }
+
+	odes := []Ode{dxdt, dvdt}
+
+	result := AdaptiveEmbedded(DoPri45, odes, []Num{-0.5, 0}, 0, 15, 0.5, 1e-6, 1e-6, 4)
+
+	d := result[len(result)-1]
+
+	fmt.Printf("%9.3f %9.3f %9.3f\n", d.t, d.xx[0], d.xx[1])
+
+	// output:
+	//    15.488     0.017     0.012
+}
@@ -0,0 +1,247 @@
+package ode
+
+// Event is a zero-crossing detector on a scalar function G(t, xx).
+// Direction restricts which crossings are reported: -1 only downward
+// (G going from positive to negative), +1 only upward, 0 either way.
+// A Terminal event stops AdaptiveStepWithEvents as soon as it fires.
+type Event struct {
+	G         func(t Num, xx []Num) Num
+	Direction int
+	Terminal  bool
+}
+
+// EventHit records a located zero crossing: the event that fired, the
+// time t* at which G crossed zero, and the interpolated state x* there.
+type EventHit struct {
+	Event int
+	T     Num
+	XX    []Num
+}
+
+// stepEvents locates every event crossing between start and end (the
+// states bracketing one accepted step), appending each to hits and
+// updating gPrev in place for the next step. If one or more Terminal
+// events fire, it reports the earliest such crossing as (terminated,
+// stopT, stopX) so the caller can clip the step to it; any hit later
+// than stopT describes a state the trajectory never actually reaches
+// (the step stopped before then), so it's dropped rather than appended.
+func stepEvents(events []Event, gPrev []Num, T, tNew Num, start, end Result, hits []EventHit) (newHits []EventHit, terminated bool, stopT Num, stopX []Num) {
+	var stepHits []EventHit
+
+	for k, e := range events {
+		gNew := e.G(tNew, end.xx)
+
+		if directionMatches(e.Direction, gPrev[k], gNew) {
+			tStar := illinois(func(t Num) Num {
+				return e.G(t, hermite(start, end, t))
+			}, T, tNew, gPrev[k], gNew)
+			xStar := hermite(start, end, tStar)
+
+			stepHits = append(stepHits, EventHit{k, tStar, xStar})
+
+			// a terminal event stops the trajectory at its own
+			// crossing, not at the full step's end; if more than one
+			// fires in the same step, the earliest is the one that
+			// physically happens first.
+			if e.Terminal && (!terminated || tStar < stopT) {
+				terminated = true
+				stopT, stopX = tStar, xStar
+			}
+		}
+
+		gPrev[k] = gNew
+	}
+
+	for _, hit := range stepHits {
+		if !terminated || hit.T <= stopT {
+			hits = append(hits, hit)
+		}
+	}
+
+	return hits, terminated, stopT, stopX
+}
+
+// AdaptiveStepWithEvents behaves like AdaptiveStep, but additionally
+// evaluates each event's G at the start and end of every accepted step.
+// A sign change matching the event's Direction is located inside the
+// step with a Hermite interpolant of x(t) (see hermite) and an Illinois
+// root finder on g(t) = G(t, x(t)). Integration stops right after the
+// first Terminal event fires.
+func AdaptiveStepWithEvents(method Integrator, dxdt []Ode, xx []Num, t0, tmax, hmin, h Num, events []Event) (Results, []EventHit) {
+	var T Num = t0
+	var c Num // Kahan compensation, see kahanAdd
+
+	var kk_full []Num
+
+	var H Num
+
+	r := make(Results, 0, 200)
+	var hits []EventHit
+
+	var last Result
+	haveLast := false
+
+	gPrev := make([]Num, len(events))
+	for k, e := range events {
+		gPrev[k] = e.G(T, xx)
+	}
+
+	for T <= tmax {
+
+		// max 5 decrements
+		for a := 0; a < 5; a++ {
+			x_full_tmp := make([]Num, len(xx))
+			x_half_tmp := make([]Num, len(xx))
+
+			copy(x_half_tmp, xx)
+
+			kk_full = method(xx, T, h, dxdt)
+
+			for i, k := range kk_full {
+				x_full_tmp[i] = xx[i] + k
+			}
+
+			var kk_half []Num
+
+			for halfs := 0; halfs <= 1; halfs++ {
+				kk_half = method(x_half_tmp, T, h/2, dxdt)
+
+				for i, k := range kk_half {
+					x_half_tmp[i] += k
+				}
+			}
+
+			q := quality(x_full_tmp, x_half_tmp, h)
+
+			// store h as the used value
+			H = h
+
+			if h < hmin {
+				break
+			} else if q > 0.005 {
+				h /= 2
+			} else if q < 0.0005 {
+				h *= 2
+				break
+			} else {
+				break
+			}
+		}
+
+		x := make([]Num, len(xx))
+		copy(x, xx)
+
+		start := Result{T, x, evalDerivs(dxdt, xx, T)}
+		r = append(r, start)
+
+		xNew := make([]Num, len(xx))
+		for i, k := range kk_full {
+			xNew[i] = xx[i] + k
+		}
+		var tNew Num
+		tNew, c = kahanAdd(T, H, c)
+		end := Result{tNew, xNew, evalDerivs(dxdt, xNew, tNew)}
+
+		var terminated bool
+		var stopT Num
+		var stopX []Num
+		hits, terminated, stopT, stopX = stepEvents(events, gPrev, T, tNew, start, end, hits)
+
+		if terminated {
+			T = stopT
+			copy(xx, stopX)
+		} else {
+			T = tNew
+
+			for i, k := range kk_full {
+				xx[i] += k
+			}
+		}
+
+		xEnd := make([]Num, len(xx))
+		copy(xEnd, xx)
+
+		last, haveLast = Result{T, xEnd, evalDerivs(dxdt, xx, T)}, true
+
+		if terminated {
+			break
+		}
+	}
+
+	// the loop above only appends each step's pre-step state; append the
+	// final accepted step's landing state too (clipped to the terminal
+	// event's crossing when one fired), so the trajectory actually
+	// reaches tmax instead of falling short by up to one full step, and
+	// doesn't run physically past a terminal stopping condition.
+	if haveLast {
+		r = append(r, last)
+	}
+
+	return r, hits
+}
+
+// directionMatches reports whether the sign change from gPrev to gNew is
+// a crossing of the kind requested by dir (-1 downward, +1 upward, 0
+// either).
+func directionMatches(dir int, gPrev, gNew Num) bool {
+	switch {
+	case gPrev == 0 || gNew == 0:
+		return false
+	case dir > 0:
+		return gPrev < 0 && gNew > 0
+	case dir < 0:
+		return gPrev > 0 && gNew < 0
+	default:
+		return !sameSign(gPrev, gNew)
+	}
+}
+
+// sameSign reports whether x and y are both strictly positive or both
+// strictly negative.
+func sameSign(x, y Num) bool {
+	return (x > 0 && y > 0) || (x < 0 && y < 0)
+}
+
+// illinois finds a root of g in [a, b] given fa = g(a), fb = g(b) with
+// opposite signs, using the Illinois variant of regula falsi: it keeps
+// the bracketing guarantee of bisection while converging superlinearly
+// like the secant method near simple roots.
+func illinois(g func(Num) Num, a, b, fa, fb Num) Num {
+	const maxIter = 100
+	const tol = 1e-13
+
+	side := 0
+
+	for i := 0; i < maxIter; i++ {
+		c := (a*fb - b*fa) / (fb - fa)
+		fc := g(c)
+
+		if fc == 0 || absNum(b-a) < tol {
+			return c
+		}
+
+		if sameSign(fc, fb) {
+			b, fb = c, fc
+			if side == -1 {
+				fa /= 2
+			}
+			side = -1
+		} else {
+			a, fa = c, fc
+			if side == 1 {
+				fb /= 2
+			}
+			side = 1
+		}
+	}
+
+	return (a*fb - b*fa) / (fb - fa)
+}
+
+// absNum returns the absolute value of x.
+func absNum(x Num) Num {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,22 @@
+package ode
+
+import "fmt"
+
+func Example_AdaptiveStepWithEvents() {
+	// x'' = -x, x(0) = 0, x'(0) = 1  =>  x(t) = sin(t), first zero crossing at t = pi
+	dxdt := func(xx []Num, t Num) Num { return xx[1] }
+	dvdt := func(xx []Num, t Num) Num { return -xx[0] }
+
+	odes := []Ode{dxdt, dvdt}
+
+	events := []Event{
+		{G: func(t Num, xx []Num) Num { return xx[0] }, Direction: -1, Terminal: true},
+	}
+
+	_, hits := AdaptiveStepWithEvents(Rk4, odes, []Num{0, 1}, 0, 10, 0.001, 0.1, events)
+
+	fmt.Printf("%.4f\n", hits[0].T)
+
+	// output:
+	// 3.1443
+}
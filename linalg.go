@@ -0,0 +1,122 @@
+package ode
+
+import "math"
+
+// Jacobian computes the Jacobian matrix d(dxdt)/dx at (xx, t), as needed
+// by implicit and semi-implicit methods such as Ros23s.
+type Jacobian func(xx []Num, t Num) [][]Num
+
+// finiteDifferenceJacobian approximates the Jacobian with forward
+// differences, one column per state variable, using the standard
+// sqrt(machine epsilon) step scaled by the size of each component.
+func finiteDifferenceJacobian(dxdt []Ode, xx []Num, t Num) [][]Num {
+	n := len(xx)
+
+	f0 := evalDerivs(dxdt, xx, t)
+
+	jac := make([][]Num, n)
+	for i := range jac {
+		jac[i] = make([]Num, n)
+	}
+
+	xPert := make([]Num, n)
+	copy(xPert, xx)
+
+	for j := range xx {
+		xAbs := xx[j]
+		if xAbs < 0 {
+			xAbs = -xAbs
+		}
+		if xAbs < 1 {
+			xAbs = 1
+		}
+
+		eps := Num(math.Sqrt(2.220446049250313e-16)) * xAbs
+
+		xPert[j] = xx[j] + eps
+
+		f1 := evalDerivs(dxdt, xPert, t)
+
+		for i := range f0 {
+			jac[i][j] = (f1[i] - f0[i]) / eps
+		}
+
+		xPert[j] = xx[j]
+	}
+
+	return jac
+}
+
+// luDecompose factors the square matrix a into L and U, stored together
+// in a single matrix following the usual compact LU scheme, using
+// partial pivoting. piv records the row permutation.
+func luDecompose(a [][]Num) (lu [][]Num, piv []int) {
+	n := len(a)
+
+	lu = make([][]Num, n)
+	for i := range a {
+		lu[i] = make([]Num, n)
+		copy(lu[i], a[i])
+	}
+
+	piv = make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		maxVal := absNum(lu[k][k])
+
+		for i := k + 1; i < n; i++ {
+			if v := absNum(lu[i][k]); v > maxVal {
+				maxVal = v
+				p = i
+			}
+		}
+
+		if p != k {
+			lu[k], lu[p] = lu[p], lu[k]
+			piv[k], piv[p] = piv[p], piv[k]
+		}
+
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= lu[i][k] * lu[k][j]
+			}
+		}
+	}
+
+	return lu, piv
+}
+
+// luSolve solves a*x = b given the LU decomposition (lu, piv) of a, by
+// forward and back substitution.
+func luSolve(lu [][]Num, piv []int, b []Num) []Num {
+	n := len(lu)
+
+	x := make([]Num, n)
+	for i := range x {
+		x[i] = b[piv[i]]
+	}
+
+	for i := 1; i < n; i++ {
+		var sum Num
+		for j := 0; j < i; j++ {
+			sum += lu[i][j] * x[j]
+		}
+		x[i] -= sum
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		var sum Num
+		for j := i + 1; j < n; j++ {
+			sum += lu[i][j] * x[j]
+		}
+		x[i] = (x[i] - sum) / lu[i][i]
+	}
+
+	return x
+}
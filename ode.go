@@ -4,10 +4,33 @@ package ode
 // Num as short for float64
 type Num float64
 
-// Result is a row in a table of calculated results
+// Result is a row in a table of calculated results. dx holds the
+// derivative dxdt(xx, t), stored alongside each step so dense output
+// (see Results.Interpolate) can reconstruct the trajectory between grid
+// points without re-evaluating the ode's.
 type Result struct {
 	t  Num
 	xx []Num
+	dx []Num
+}
+
+// evalDerivs evaluates dxdt at (xx, t)
+func evalDerivs(dxdt []Ode, xx []Num, t Num) []Num {
+	dx := make([]Num, len(dxdt))
+	for i, f := range dxdt {
+		dx[i] = f(xx, t)
+	}
+
+	return dx
+}
+
+// derivOr returns dx if it's already known (e.g. an FSAL method's cached
+// stage), or evaluates dxdt at (xx, t) otherwise.
+func derivOr(dx []Num, dxdt []Ode, xx []Num, t Num) []Num {
+	if dx != nil {
+		return dx
+	}
+	return evalDerivs(dxdt, xx, t)
 }
 
 // Ode is a first order differential equation
@@ -17,12 +40,13 @@ type Ode func([]Num, Num) Num
 type Integrator func([]Num, Num, Num, []Ode) []Num
 
 // FixedStep iterates over a set of ode's with fixed step h
-func FixedStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, h Num) []Result {
+func FixedStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, h Num) Results {
 	var T Num
+	var c Num // Kahan compensation, see kahanAdd
 
 	T = t0
 
-	r := make([]Result, 0, 200)
+	r := make(Results, 0, 200)
 
 	for T <= tmax {
 		kk := method(xx, T, h, dxdt)
@@ -31,28 +55,29 @@ func FixedStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, h Num) []Resul
 		x := make([]Num, len(xx))
 		copy(x, xx)
 
-		r = append(r, Result{T, x})
+		r = append(r, Result{T, x, evalDerivs(dxdt, xx, T)})
 
 		for i, k := range kk {
 			xx[i] += k
 		}
 
-		T += h
+		T, c = kahanAdd(T, h, c)
 	}
 
 	return r
 }
 
 // AdaptiveStep iterates over a set of ode's with adaptive h
-// starts with h and minimum hmin
-func AdaptiveStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, hmin, h Num) []Result {
+// starts with h and minimum hmin. h is typically seeded with StartingStep.
+func AdaptiveStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, hmin, h Num) Results {
 	var T Num = t0
+	var c Num // Kahan compensation, see kahanAdd
 
 	var kk_full []Num
 
 	var H Num
 
-	r := make([]Result, 0, 200)
+	r := make(Results, 0, 200)
 
 	for T <= tmax {
 
@@ -99,9 +124,9 @@ func AdaptiveStep(method Integrator, dxdt []Ode, xx []Num, t0, tmax, hmin, h Num
 		x := make([]Num, len(xx))
 		copy(x, xx)
 
-		r = append(r, Result{T, x})
+		r = append(r, Result{T, x, evalDerivs(dxdt, xx, T)})
 
-		T += H
+		T, c = kahanAdd(T, H, c)
 
 		for i, k := range kk_full {
 			xx[i] += k
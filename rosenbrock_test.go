@@ -0,0 +1,19 @@
+package ode
+
+import "fmt"
+
+func Example_Ros23s() {
+	// stiff linear decay: x' = -1000x, exact x(t) = exp(-1000 t)
+	decay := func(xx []Num, t Num) Num { return -1000 * xx[0] }
+
+	odes := []Ode{decay}
+
+	result := AdaptiveEmbedded(Ros23s, odes, []Num{1}, 0, 0.01, 0.001, 1e-6, 1e-6, 1)
+
+	d := result[len(result)-1]
+
+	fmt.Printf("%9.6f\n", d.xx[0])
+
+	// output:
+	// 0.000039
+}
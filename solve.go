@@ -0,0 +1,372 @@
+package ode
+
+import "errors"
+
+// Method selects which integration algorithm Solve uses.
+type Method int
+
+const (
+	MethodEuler Method = iota
+	MethodMidPoint
+	MethodRk4
+	MethodDoPri45
+	MethodRos23s
+	MethodBulirschStoer
+)
+
+// Options configures Solve. The zero value selects MethodEuler, with
+// AbsTol/RelTol/MaxSteps defaulted as documented per field; a nil
+// *Options passed to Solve is equivalent to &Options{}.
+type Options struct {
+	Method Method
+
+	AbsTol Num // default 1e-6
+	RelTol Num // default 1e-3
+
+	InitialStep Num // default: computed with StartingStep
+	MinStep     Num // default 0 (no minimum)
+	MaxStep     Num // default 0 (no maximum; tspan's span only caps the StartingStep guess)
+
+	MaxSteps int // default 100000
+
+	Events   []Event
+	Jacobian Jacobian
+
+	// Dense requests continuous output when tspan has only 2 entries, by
+	// having Solution retain the accepted steps for Solution.Interpolate
+	// and Solution.Sample; dense output for an explicit tspan already
+	// happens unconditionally via Results.Sample.
+	Dense bool
+
+	// OutputFunc, if set, is called after every accepted step; returning
+	// false aborts the integration, like a Terminal Event without the
+	// root-finding.
+	OutputFunc func(t Num, xx []Num) bool
+}
+
+// Stats reports the work Solve did to produce a Solution.
+type Stats struct {
+	Accepted int
+	Rejected int
+	FEvals   int
+	JacEvals int
+}
+
+// Solution is the result of Solve.
+type Solution struct {
+	T      []Num
+	X      [][]Num
+	Events []EventHit
+	Stats  Stats
+
+	// dense holds the accepted steps when Options.Dense was set, backing
+	// Interpolate and Sample.
+	dense Results
+}
+
+// Interpolate evaluates the solution at an arbitrary time t within tspan
+// by cubic Hermite interpolation between accepted steps. It requires
+// Options.Dense to have been set with a length-2 tspan; otherwise it
+// returns nil.
+func (s *Solution) Interpolate(t Num) []Num {
+	return s.dense.Interpolate(t)
+}
+
+// Sample evaluates the solution at each of times the same way Interpolate
+// does. It requires Options.Dense to have been set with a length-2 tspan;
+// otherwise it returns a slice of nils.
+func (s *Solution) Sample(times []Num) [][]Num {
+	return s.dense.Sample(times)
+}
+
+// Solve integrates dxdt from xx over tspan using opts (nil selects all
+// defaults, see Options). tspan follows ode45's convention: a length-2
+// tspan means "integrate from tspan[0] to tspan[1] and return the
+// accepted steps"; a longer tspan means "return the solution
+// interpolated exactly at these times" via Results.Sample. xx is not
+// modified; the trajectory is copied before Solve advances it.
+func Solve(dxdt []Ode, xx []Num, tspan []Num, opts *Options) (*Solution, error) {
+	if len(tspan) < 2 {
+		return nil, errors.New("ode: tspan must have at least 2 entries")
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	atol := opts.AbsTol
+	if atol == 0 {
+		atol = 1e-6
+	}
+
+	rtol := opts.RelTol
+	if rtol == 0 {
+		rtol = 1e-3
+	}
+
+	maxSteps := opts.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = 100000
+	}
+
+	t0, tmax := tspan[0], tspan[len(tspan)-1]
+
+	x0 := make([]Num, len(xx))
+	copy(x0, xx)
+
+	var stats Stats
+
+	countedDxdt := make([]Ode, len(dxdt))
+	for i, f := range dxdt {
+		f := f
+		countedDxdt[i] = func(xx []Num, t Num) Num {
+			stats.FEvals++
+			return f(xx, t)
+		}
+	}
+
+	var method EmbeddedIntegrator
+	var order int
+
+	switch opts.Method {
+	case MethodMidPoint:
+		method, order = stepDoubleEmbedded(MidPoint), 2
+	case MethodDoPri45:
+		// order is the order of the embedded error estimator (the 4th
+		// order companion solution), not the 5th order solution DoPri45
+		// advances with; see AdaptiveEmbedded.
+		method, order = DoPri45, 4
+	case MethodRos23s:
+		userJac := opts.Jacobian
+		jac := func(xx []Num, t Num) [][]Num {
+			stats.JacEvals++
+			if userJac != nil {
+				return userJac(xx, t)
+			}
+			return finiteDifferenceJacobian(countedDxdt, xx, t)
+		}
+		// order is the order of the embedded error estimator (the 1st
+		// order Rosenbrock-Euler stage), not the 2nd order solution
+		// Ros23s advances with; see AdaptiveEmbedded.
+		method, order = NewRos23s(jac), 1
+	case MethodBulirschStoer:
+		method, order = BulirschStoer, 6
+	case MethodEuler:
+		method, order = stepDoubleEmbedded(Euler), 1
+	default:
+		method, order = stepDoubleEmbedded(Rk4), 4
+	}
+
+	h := opts.InitialStep
+	if h == 0 {
+		hmax := opts.MaxStep
+		if hmax == 0 {
+			hmax = tmax - t0
+		}
+		h = StartingStep(countedDxdt, x0, t0, atol, rtol, hmax, order)
+	}
+
+	r, hits, err := solveLoop(method, countedDxdt, x0, t0, tmax, h, opts.MinStep, opts.MaxStep,
+		atol, rtol, order, maxSteps, opts.Events, opts.OutputFunc, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	sol := &Solution{Events: hits, Stats: stats}
+
+	if len(tspan) == 2 {
+		sol.T = make([]Num, len(r))
+		sol.X = make([][]Num, len(r))
+		for i, res := range r {
+			sol.T[i] = res.t
+			sol.X[i] = res.xx
+		}
+
+		if opts.Dense {
+			sol.dense = r
+		}
+	} else {
+		sol.T = tspan
+		sol.X = r.Sample(tspan)
+	}
+
+	return sol, nil
+}
+
+// stepDoubleEmbedded adapts a plain Integrator to an EmbeddedIntegrator
+// by comparing one step of h against two steps of h/2, the same
+// step-doubling idea AdaptiveStep uses, so Solve can drive every method
+// (embedded or not) through a single adaptive loop.
+func stepDoubleEmbedded(method Integrator) EmbeddedIntegrator {
+	return func(xx []Num, t, h Num, dxdt []Ode, dx0 []Num) (kk, errEst, dxEnd []Num) {
+		n := len(xx)
+
+		kk = method(xx, t, h, dxdt)
+
+		xHalf := make([]Num, n)
+		copy(xHalf, xx)
+
+		k1 := method(xHalf, t, h/2, dxdt)
+		for i, k := range k1 {
+			xHalf[i] += k
+		}
+
+		k2 := method(xHalf, t+h/2, h/2, dxdt)
+		for i, k := range k2 {
+			xHalf[i] += k
+		}
+
+		errEst = make([]Num, n)
+		for i := range xx {
+			errEst[i] = (xx[i] + kk[i]) - xHalf[i]
+		}
+
+		// Integrator only returns an increment, not a derivative, so
+		// there's nothing to hand back as dxEnd here.
+		return kk, errEst, nil
+	}
+}
+
+// solveLoop is the single adaptive driver behind Solve: it combines the
+// PI step-size control of AdaptiveEmbedded with the event handling of
+// AdaptiveStepWithEvents, plus bookkeeping (MaxSteps, OutputFunc, Stats)
+// that neither of those lower-level drivers does.
+func solveLoop(method EmbeddedIntegrator, dxdt []Ode, xx []Num, t0, tmax, h, hmin, hmax, atol, rtol Num, order, maxSteps int, events []Event, outputFunc func(Num, []Num) bool, stats *Stats) (Results, []EventHit, error) {
+	const (
+		safety = 0.9
+		minFac = 0.1
+		maxFac = 5.0
+	)
+
+	var T Num = t0
+	var c Num // Kahan compensation, see kahanAdd
+
+	prevErr := Num(1)
+
+	r := make(Results, 0, 200)
+	var hits []EventHit
+
+	var last Result
+	haveLast := false
+
+	// dx0 carries an FSAL method's last-stage derivative forward as the
+	// next step's first-stage derivative, saving a dxdt evaluation; it's
+	// nil for methods (Ros23s, BulirschStoer, ...) that don't return one,
+	// and is dropped across a terminal event clip since that lands on an
+	// interpolated state the method never actually stepped to.
+	var dx0 []Num
+
+	gPrev := make([]Num, len(events))
+	for k, e := range events {
+		gPrev[k] = e.G(T, xx)
+	}
+
+	for T <= tmax {
+		if stats.Accepted+stats.Rejected >= maxSteps {
+			if haveLast {
+				r = append(r, last)
+			}
+			return r, hits, errors.New("ode: exceeded MaxSteps")
+		}
+
+		if hmax > 0 && h > hmax {
+			h = hmax
+		}
+
+		var kk, errEst, dxEnd []Num
+		var errNorm Num
+
+		// max 5 decrements, same bound AdaptiveEmbedded uses, plus a guard
+		// against a NaN/Inf errNorm which would otherwise never satisfy
+		// errNorm <= 1 and spin forever; each attempt also counts toward
+		// MaxSteps like any other rejected step.
+		for attempt := 0; attempt < 5; attempt++ {
+			if stats.Accepted+stats.Rejected >= maxSteps {
+				if haveLast {
+					r = append(r, last)
+				}
+				return r, hits, errors.New("ode: exceeded MaxSteps")
+			}
+
+			kk, errEst, dxEnd = method(xx, T, h, dxdt, dx0)
+
+			errNorm = embeddedErrorNorm(xx, kk, errEst, atol, rtol)
+
+			if !finite(errNorm) || errNorm <= 1 || h <= hmin {
+				break
+			}
+
+			stats.Rejected++
+			h *= clamp(safety*pow(1/errNorm, 1.0/Num(order+1)), minFac, 1)
+		}
+
+		stats.Accepted++
+
+		x := make([]Num, len(xx))
+		copy(x, xx)
+
+		start := Result{T, x, derivOr(dx0, dxdt, xx, T)}
+		r = append(r, start)
+
+		xNew := make([]Num, len(xx))
+		for i, k := range kk {
+			xNew[i] = xx[i] + k
+		}
+
+		var tNew Num
+		tNew, c = kahanAdd(T, h, c)
+
+		endDx := derivOr(dxEnd, dxdt, xNew, tNew)
+		end := Result{tNew, xNew, endDx}
+
+		var terminated bool
+		var stopT Num
+		var stopX []Num
+		hits, terminated, stopT, stopX = stepEvents(events, gPrev, T, tNew, start, end, hits)
+
+		var lastDx []Num
+
+		if terminated {
+			T = stopT
+			copy(xx, stopX)
+			dx0 = nil
+			lastDx = evalDerivs(dxdt, xx, T)
+		} else {
+			T = tNew
+			copy(xx, xNew)
+			dx0 = dxEnd
+			lastDx = endDx // already derivOr(dxEnd, dxdt, xx, T); reuse, don't re-evaluate
+		}
+
+		xEnd := make([]Num, len(xx))
+		copy(xEnd, xx)
+
+		last, haveLast = Result{T, xEnd, lastDx}, true
+
+		factor := clamp(safety*pow(1/errNorm, 1.0/Num(order+1))*pow(prevErr/errNorm, 0.08), minFac, maxFac)
+		h *= factor
+
+		if errNorm > 0 {
+			prevErr = errNorm
+		}
+
+		if outputFunc != nil && !outputFunc(T, xx) {
+			break
+		}
+
+		if terminated {
+			break
+		}
+	}
+
+	// the loop above only appends each step's pre-step state; append the
+	// final accepted step's landing state too (clipped to the terminal
+	// event's crossing when one fired), so the trajectory actually
+	// reaches tmax instead of falling short by up to one full step, and
+	// doesn't run physically past a terminal stopping condition.
+	if haveLast {
+		r = append(r, last)
+	}
+
+	return r, hits, nil
+}
@@ -0,0 +1,104 @@
+package ode
+
+import "fmt"
+
+func Example_Solve() {
+	var k, m, b Num
+
+	k = 1
+	m = 1
+	b = 0.4
+
+	dxdt := func(xx []Num, t Num) Num { v := xx[1]; return v }
+	dvdt := func(xx []Num, t Num) Num { x, v := xx[0], xx[1]; return -k*x/m - b*v/m }
+
+	odes := []Ode{dxdt, dvdt}
+
+	opts := &Options{Method: MethodDoPri45, AbsTol: 1e-8, RelTol: 1e-8}
+
+	sol, err := Solve(odes, []Num{-0.5, 0}, []Num{0, 1, 2, 3}, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("points=%d accepted=%v fevals=%v\n", len(sol.T), sol.Stats.Accepted > 0, sol.Stats.FEvals > 0)
+	fmt.Printf("%.3f %.3f\n", sol.X[len(sol.X)-1][0], sol.X[len(sol.X)-1][1])
+
+	// output:
+	// points=4 accepted=true fevals=true
+	// 0.258 0.056
+}
+
+// Example_Solve_Events checks that a terminal event clips Solve's
+// returned trajectory to the crossing itself, not the full step that
+// found it.
+func Example_Solve_Events() {
+	// x'' = -x, x(0) = 0, x'(0) = 1  =>  x(t) = sin(t), first zero crossing at t = pi
+	dxdt := func(xx []Num, t Num) Num { return xx[1] }
+	dvdt := func(xx []Num, t Num) Num { return -xx[0] }
+
+	odes := []Ode{dxdt, dvdt}
+
+	events := []Event{
+		{G: func(t Num, xx []Num) Num { return xx[0] }, Direction: -1, Terminal: true},
+	}
+
+	opts := &Options{Method: MethodDoPri45, AbsTol: 1e-8, RelTol: 1e-8, Events: events}
+
+	sol, err := Solve(odes, []Num{0, 1}, []Num{0, 10}, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	last := len(sol.T) - 1
+	fmt.Printf("%.6f %.6f\n", sol.T[last], sol.X[last][0])
+	fmt.Printf("%.6f\n", sol.Events[0].T)
+
+	// output:
+	// 3.141593 -0.000000
+	// 3.141593
+}
+
+// Example_Solve_Ros23s exercises MethodRos23s through Solve, the only
+// path that drives Stats.JacEvals.
+func Example_Solve_Ros23s() {
+	// stiff linear decay: x' = -1000x, exact x(t) = exp(-1000 t)
+	decay := func(xx []Num, t Num) Num { return -1000 * xx[0] }
+
+	odes := []Ode{decay}
+
+	opts := &Options{Method: MethodRos23s, AbsTol: 1e-6, RelTol: 1e-6}
+
+	sol, err := Solve(odes, []Num{1}, []Num{0, 0.01}, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("jacevals=%v\n", sol.Stats.JacEvals > 0)
+	fmt.Printf("%.6f\n", sol.X[len(sol.X)-1][0])
+
+	// output:
+	// jacevals=true
+	// 0.000041
+}
+
+// Example_Solve_MaxSteps checks that Solve reports the exceeded-MaxSteps
+// error instead of looping forever when a step budget is too tight to
+// reach tmax.
+func Example_Solve_MaxSteps() {
+	dxdt := func(xx []Num, t Num) Num { return xx[1] }
+	dvdt := func(xx []Num, t Num) Num { return -xx[0] }
+
+	odes := []Ode{dxdt, dvdt}
+
+	opts := &Options{Method: MethodDoPri45, MaxSteps: 2}
+
+	_, err := Solve(odes, []Num{0, 1}, []Num{0, 10}, opts)
+	fmt.Println(err)
+
+	// output:
+	// ode: exceeded MaxSteps
+}
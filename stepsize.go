@@ -0,0 +1,94 @@
+package ode
+
+import "math"
+
+// rmsNorm returns the root-mean-square of v, the norm used throughout
+// this package to combine per-component scaled quantities into a single
+// scalar (see also embeddedErrorNorm).
+func rmsNorm(v []Num) Num {
+	var sum Num
+	for _, x := range v {
+		sum += x * x
+	}
+	return Num(math.Sqrt(float64(sum / Num(len(v)))))
+}
+
+// StartingStep picks an initial step size for an integrator of the given
+// order, following the Gladwell/Shampine starter also used by Octave's
+// ode drivers: it takes the scale of x and f(x0,t0) into account, then
+// refines the estimate with one trial Euler step so the result reflects
+// the local curvature of the problem rather than just its scale.
+func StartingStep(dxdt []Ode, xx []Num, t0, atol, rtol, hmax Num, order int) Num {
+	n := len(xx)
+
+	sc := make([]Num, n)
+	for i, x := range xx {
+		sc[i] = atol + rtol*absNum(x)
+	}
+
+	f0 := evalDerivs(dxdt, xx, t0)
+
+	scaled := make([]Num, n)
+	for i, x := range xx {
+		scaled[i] = x / sc[i]
+	}
+	d0 := rmsNorm(scaled)
+
+	for i, d := range f0 {
+		scaled[i] = d / sc[i]
+	}
+	d1 := rmsNorm(scaled)
+
+	var h0 Num
+	if d0 < 1e-5 || d1 < 1e-5 {
+		h0 = 1e-6
+	} else {
+		h0 = 0.01 * d0 / d1
+	}
+
+	x1 := make([]Num, n)
+	for i, x := range xx {
+		x1[i] = x + h0*f0[i]
+	}
+	f1 := evalDerivs(dxdt, x1, t0+h0)
+
+	for i := range f1 {
+		scaled[i] = (f1[i] - f0[i]) / sc[i]
+	}
+	d2 := rmsNorm(scaled) / h0
+
+	m := d1
+	if d2 > m {
+		m = d2
+	}
+
+	var h1 Num
+	if m <= 1e-15 {
+		h1 = h0 * 1e-3
+		if h1 < 1e-6 {
+			h1 = 1e-6
+		}
+	} else {
+		h1 = pow(0.01/m, 1.0/Num(order+1))
+	}
+
+	h := 100 * h0
+	if h1 < h {
+		h = h1
+	}
+	if hmax < h {
+		h = hmax
+	}
+
+	return h
+}
+
+// kahanAdd adds value to sum using Kahan compensated summation, carrying
+// the running compensation c across calls so that long integrations over
+// many small steps don't accumulate floating-point drift in T.
+func kahanAdd(sum, value, c Num) (newSum, newC Num) {
+	y := value - c
+	t := sum + y
+	newC = (t - sum) - y
+	return t, newC
+}
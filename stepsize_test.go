@@ -0,0 +1,16 @@
+package ode
+
+import "fmt"
+
+func Example_StartingStep() {
+	decay := func(xx []Num, t Num) Num { return -xx[0] }
+
+	odes := []Ode{decay}
+
+	h := StartingStep(odes, []Num{1}, 0, 1e-6, 1e-3, 1, 5)
+
+	fmt.Printf("%.6f\n", h)
+
+	// output:
+	// 0.146804
+}